@@ -0,0 +1,333 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+
+	"github.com/hellofresh/klepto/pkg/database/retry"
+)
+
+// BulkLoader is implemented by Dumpers that can load batched rows directly
+// into their target connection via the database's native bulk-load facility
+// (e.g. MySQL's LOAD DATA LOCAL INFILE, Postgres' COPY), rather than issuing
+// row-by-row or multi-row INSERT statements.
+type BulkLoader interface {
+	// BulkLoad drains rowChan into table using the database's native bulk
+	// loader, falling back to a regular batched INSERT for tables that can't
+	// safely take that path (e.g. ones with triggers or generated columns).
+	BulkLoad(ctx context.Context, table string, rowChan <-chan Row) error
+}
+
+var _ BulkLoader = (*MySQLDumper)(nil)
+
+// BulkLoad drains rowChan into table via LOAD DATA LOCAL INFILE, streaming
+// batches of d.opts.BulkBatchSize rows (or sooner, once d.opts.BulkBatchBytes
+// is exceeded) through an in-memory io.Pipe rather than buffering the whole
+// table. Tables with triggers or generated columns can't safely go through
+// LOAD DATA, since it bypasses the trigger body's column computation for
+// generated columns in older MySQL versions, so those fall back to the
+// regular batched INSERT path.
+func (d *MySQLDumper) BulkLoad(ctx context.Context, table string, rowChan <-chan Row) error {
+	unsafe, err := d.hasTriggersOrGeneratedColumns(ctx, table)
+	if err != nil {
+		return err
+	}
+	if unsafe {
+		return d.bulkLoadViaInsert(ctx, table, rowChan)
+	}
+
+	batch := make([]Row, 0, d.opts.batchSize())
+	batchBytes := 0
+	bytesLimit := d.opts.batchBytesLimit()
+
+	flush := func(batch []Row) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		return retry.Do(ctx, d.opts.Retry, func(ctx context.Context) error {
+			return d.loadDataInfile(ctx, table, batch)
+		})
+	}
+
+	for {
+		select {
+		case row, ok := <-rowChan:
+			if !ok {
+				return flush(batch)
+			}
+
+			batch = append(batch, row)
+			batchBytes += rowSize(row)
+
+			if len(batch) >= d.opts.batchSize() || (bytesLimit > 0 && batchBytes >= bytesLimit) {
+				if err := flush(batch); err != nil {
+					return err
+				}
+				batch = make([]Row, 0, d.opts.batchSize())
+				batchBytes = 0
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// loadDataInfile streams batch as CSV through an io.Pipe registered with the
+// go-sql-driver/mysql client-side reader handler, and issues a single LOAD
+// DATA LOCAL INFILE statement pulling from it.
+func (d *MySQLDumper) loadDataInfile(ctx context.Context, table string, batch []Row) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	columns := make([]string, 0, len(batch[0]))
+	for column := range batch[0] {
+		columns = append(columns, column)
+	}
+
+	pr, pw := io.Pipe()
+	handle := fmt.Sprintf("klepto-bulk-%s-%p", table, batch)
+	mysqldriver.RegisterReaderHandler(handle, func() io.Reader { return pr })
+	defer mysqldriver.DeregisterReaderHandler(handle)
+
+	var writeErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer pw.Close()
+		writeErr = writeCSV(pw, columns, batch)
+	}()
+
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = fmt.Sprintf("`%s`", column)
+	}
+
+	execCtx, cancel := withTimeout(ctx, d.opts.WriteTimeout)
+	defer cancel()
+
+	_, err := d.conn.ExecContext(execCtx, fmt.Sprintf(
+		"LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE `%s` FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' LINES TERMINATED BY '\\n' (%s)",
+		handle, table, joinColumns(quotedColumns),
+	))
+	if err != nil {
+		// The driver may abandon the statement without fully draining pr,
+		// leaving the writer goroutine blocked on pw.Write forever. Close the
+		// read end with the error so it unblocks instead of leaking.
+		pr.CloseWithError(err)
+	}
+
+	wg.Wait()
+	if err != nil {
+		return fmt.Errorf("failed to bulk load %q: %w", table, err)
+	}
+	return writeErr
+}
+
+// writeCSV renders batch as rows in the dialect loadDataInfile's statement
+// declares (FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' LINES
+// TERMINATED BY '\n', MySQL's default ESCAPED BY '\\'). encoding/csv is not
+// used here: it doesn't escape backslashes at all and escapes a quote by
+// doubling it, neither of which matches what LOAD DATA unescapes on the way
+// back in, so a value containing either would come out corrupted.
+func writeCSV(w io.Writer, columns []string, batch []Row) error {
+	fields := make([]string, len(columns))
+	for _, row := range batch {
+		for i, column := range columns {
+			fields[i] = formatCSVValue(row[column])
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", strings.Join(fields, ",")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatCSVValue renders a column value for a LOAD DATA INFILE field. NULL is
+// the bare, unquoted `\N` marker LOAD DATA recognises under its default
+// ESCAPED BY '\\'; every other value is wrapped in double quotes (matching
+// OPTIONALLY ENCLOSED BY '"'), with backslashes and embedded quotes
+// backslash-escaped so LOAD DATA's own unescaping reconstructs them exactly -
+// encoding/csv's quote-doubling would otherwise load a literal `""` into the
+// value. Backslash must be escaped before the quote, or escaping the quote
+// would double-escape a backslash that precedes it.
+func formatCSVValue(value interface{}) string {
+	if value == nil {
+		return `\N`
+	}
+
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func joinColumns(columns []string) string {
+	out := ""
+	for i, column := range columns {
+		if i > 0 {
+			out += ", "
+		}
+		out += column
+	}
+	return out
+}
+
+// buildParameterizedInsert renders batch as a single multi-row INSERT
+// statement using `?` placeholders, with the row values returned separately
+// as args for ExecContext. All rows in a batch are expected to share the
+// same columns.
+func buildParameterizedInsert(table string, batch []Row) (string, []interface{}) {
+	columns := make([]string, 0, len(batch[0]))
+	for column := range batch[0] {
+		columns = append(columns, column)
+	}
+
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = fmt.Sprintf("`%s`", column)
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	tuple := fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
+
+	valueTuples := make([]string, len(batch))
+	args := make([]interface{}, 0, len(batch)*len(columns))
+	for i, row := range batch {
+		valueTuples[i] = tuple
+		for _, column := range columns {
+			args = append(args, row[column])
+		}
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO `%s` (%s) VALUES %s",
+		table,
+		strings.Join(quotedColumns, ", "),
+		strings.Join(valueTuples, ", "),
+	)
+	return query, args
+}
+
+// bulkLoadViaInsert is the fallback path for tables LOAD DATA can't safely
+// handle: it batches rows into the same shape of multi-row INSERT DumpTable
+// writes, but executes them as a parameterized statement against d.conn
+// instead of writing SQL text to a stream, so values never need to be
+// interpolated (and escaped) into the query itself.
+func (d *MySQLDumper) bulkLoadViaInsert(ctx context.Context, table string, rowChan <-chan Row) error {
+	batch := make([]Row, 0, d.opts.batchSize())
+	batchBytes := 0
+	bytesLimit := d.opts.batchBytesLimit()
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		batchBytes = 0
+		query, args := buildParameterizedInsert(table, batch)
+		err := retry.Do(ctx, d.opts.Retry, func(ctx context.Context) error {
+			ctx, cancel := withTimeout(ctx, d.opts.WriteTimeout)
+			defer cancel()
+			_, err := d.conn.ExecContext(ctx, query, args...)
+			return err
+		})
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		select {
+		case row, ok := <-rowChan:
+			if !ok {
+				return flush()
+			}
+			batch = append(batch, row)
+			batchBytes += rowSize(row)
+			if len(batch) >= d.opts.batchSize() || (bytesLimit > 0 && batchBytes >= bytesLimit) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// BulkLoadAll runs a BulkLoad per table concurrently, bounded by maxConns
+// simultaneous loads so the target database's connection pool isn't
+// overwhelmed. rowChans must have one entry per table in tables. The first
+// error from any table is returned once every load has finished.
+func BulkLoadAll(ctx context.Context, loader BulkLoader, tables []string, rowChans map[string]<-chan Row, maxConns int) error {
+	if maxConns <= 0 {
+		maxConns = 1
+	}
+
+	sem := make(chan struct{}, maxConns)
+	errs := make(chan error, len(tables))
+	var wg sync.WaitGroup
+
+	for _, table := range tables {
+		table := table
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			errs <- loader.BulkLoad(ctx, table, rowChans[table])
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasTriggersOrGeneratedColumns reports whether table has triggers or
+// generated columns, either of which make LOAD DATA LOCAL INFILE an unsafe
+// bulk-load path for it.
+func (d *MySQLDumper) hasTriggersOrGeneratedColumns(ctx context.Context, table string) (unsafe bool, err error) {
+	err = retry.Do(ctx, d.opts.Retry, func(ctx context.Context) error {
+		ctx, cancel := withTimeout(ctx, d.opts.ReadTimeout)
+		defer cancel()
+
+		var count int
+		row := d.conn.QueryRowContext(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM information_schema.TRIGGERS WHERE EVENT_OBJECT_TABLE = ?) +
+				(SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_NAME = ? AND GENERATION_EXPRESSION <> '')
+		`, table, table)
+		if err := row.Scan(&count); err != nil {
+			return err
+		}
+		unsafe = count > 0
+		return nil
+	})
+	return
+}
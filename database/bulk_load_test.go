@@ -0,0 +1,46 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatCSVValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil is the bare NULL marker", nil, `\N`},
+		{"string is quoted", "hello", `"hello"`},
+		{"[]byte is quoted like a string", []byte("hello"), `"hello"`},
+		{"backslash is escaped", `C:\path`, `"C:\\path"`},
+		{"embedded quote is escaped", `say "hi"`, `"say \"hi\""`},
+		{"backslash before quote escapes in the right order", `\"`, `"\\\""`},
+		{"int is quoted like everything else", 42, `"42"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCSVValue(tt.value); got != tt.want {
+				t.Errorf("formatCSVValue(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf strings.Builder
+	batch := []Row{
+		{"id": 1, "name": `a\b`, "note": nil},
+	}
+
+	if err := writeCSV(&buf, []string{"id", "name", "note"}, batch); err != nil {
+		t.Fatalf("writeCSV: %v", err)
+	}
+
+	want := "\"1\",\"a\\\\b\",\\N\n"
+	if buf.String() != want {
+		t.Errorf("writeCSV output = %q, want %q", buf.String(), want)
+	}
+}
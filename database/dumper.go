@@ -0,0 +1,22 @@
+package database
+
+import (
+	"context"
+	"io"
+
+	"github.com/hellofresh/klepto/pkg/database"
+)
+
+// Row is pkg/database.Row, the type a reader.Reader's ReadSubset emits. It is
+// aliased here rather than redefined so a row read off a Reader's rowChan can
+// be handed straight to a Dumper without conversion.
+type Row = database.Row
+
+// Dumper writes a database's structure and row data to an output stream.
+type Dumper interface {
+	// DumpStructure writes the database's structure to the provided stream.
+	DumpStructure(ctx context.Context, w io.Writer) error
+	// DumpTable writes every row received on rowChan as an INSERT statement
+	// for table to the provided stream, until rowChan is closed or ctx is done.
+	DumpTable(ctx context.Context, w io.Writer, table string, rowChan <-chan Row) error
+}
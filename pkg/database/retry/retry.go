@@ -0,0 +1,116 @@
+// Package retry wraps Reader/Dumper SQL calls with exponential backoff, so a
+// transient blip against a busy production replica (a deadlock, a lock wait
+// timeout, a dropped connection) doesn't cost an otherwise healthy multi-hour
+// dump.
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// DefaultInitialBackoff is used when Opts.InitialBackoff is unset, so a zero
+// Opts doesn't busy-spin retries with no delay between them.
+const DefaultInitialBackoff = 100 * time.Millisecond
+
+// Opts configures the backoff schedule used by Do.
+type Opts struct {
+	// Max is the maximum number of retries after the initial attempt fails.
+	// fn is therefore called at most Max+1 times. Zero or negative means no
+	// retry: fn is called exactly once.
+	Max int
+	// InitialBackoff is the delay before the first retry. Zero means use
+	// DefaultInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the doubling backoff between retries; it stops doubling
+	// once reached. Jitter of up to 50% is added on top of the capped value,
+	// so an actual delay can be up to 1.5x MaxBackoff. Zero means no cap.
+	MaxBackoff time.Duration
+}
+
+// retriableMySQLErrors are the MySQL error numbers worth retrying:
+// 1213 deadlock found, 1205 lock wait timeout, 2006 server has gone away,
+// 2013 lost connection during query.
+var retriableMySQLErrors = map[uint16]bool{
+	1213: true,
+	1205: true,
+	2006: true,
+	2013: true,
+}
+
+// retriablePostgresCodes are the Postgres SQLSTATE codes worth retrying:
+// 40001 serialization_failure, 40P01 deadlock_detected.
+var retriablePostgresCodes = map[string]bool{
+	"40001": true,
+	"40P01": true,
+}
+
+// IsRetriable classifies err as a transient failure worth retrying, as
+// opposed to a permanent one (bad syntax, missing permissions) that will
+// never succeed no matter how many times it's retried.
+func IsRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return retriableMySQLErrors[mysqlErr.Number]
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return retriablePostgresCodes[string(pqErr.Code)]
+	}
+
+	return false
+}
+
+// Do calls fn, retrying up to opts.Max times (so fn is called at most
+// opts.Max+1 times total) with exponential backoff and jitter whenever fn's
+// error is retriable per IsRetriable. It gives up immediately on a
+// non-retriable error, and stops retrying if ctx is done.
+func Do(ctx context.Context, opts Opts, fn func(ctx context.Context) error) error {
+	backoff := opts.InitialBackoff
+	if backoff <= 0 {
+		backoff = DefaultInitialBackoff
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || !IsRetriable(err) || attempt >= opts.Max {
+			return err
+		}
+
+		select {
+		case <-time.After(jitter(backoff)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		backoff *= 2
+		if opts.MaxBackoff > 0 && backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
+
+// jitter returns d plus up to 50% random jitter, so a fleet of readers
+// retrying the same flaky replica don't all hammer it in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
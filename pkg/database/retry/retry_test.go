@@ -0,0 +1,101 @@
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestIsRetriable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad conn", driver.ErrBadConn, true},
+		{"mysql deadlock", &mysqldriver.MySQLError{Number: 1213}, true},
+		{"mysql lock wait timeout", &mysqldriver.MySQLError{Number: 1205}, true},
+		{"mysql syntax error", &mysqldriver.MySQLError{Number: 1064}, false},
+		{"postgres serialization failure", &pq.Error{Code: "40001"}, true},
+		{"postgres undefined table", &pq.Error{Code: "42P01"}, false},
+		{"plain error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetriable(tt.err); got != tt.want {
+				t.Errorf("IsRetriable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	attempts := 0
+	err := Do(context.Background(), Opts{Max: 3, InitialBackoff: time.Millisecond}, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestDoGivesUpAfterMaxRetries(t *testing.T) {
+	attempts := 0
+	err := Do(context.Background(), Opts{Max: 2, InitialBackoff: time.Millisecond}, func(ctx context.Context) error {
+		attempts++
+		return driver.ErrBadConn
+	})
+	if err != driver.ErrBadConn {
+		t.Fatalf("Do err = %v, want driver.ErrBadConn", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3 (1 initial + Max 2 retries)", attempts)
+	}
+}
+
+func TestDoStopsImmediatelyOnNonRetriableError(t *testing.T) {
+	attempts := 0
+	boom := errors.New("boom")
+	err := Do(context.Background(), Opts{Max: 3, InitialBackoff: time.Millisecond}, func(ctx context.Context) error {
+		attempts++
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("Do err = %v, want boom", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestDoUsesDefaultBackoffWhenUnset(t *testing.T) {
+	start := time.Now()
+	attempts := 0
+	err := Do(context.Background(), Opts{Max: 1}, func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < DefaultInitialBackoff {
+		t.Errorf("Do returned after %s, want at least DefaultInitialBackoff (%s)", elapsed, DefaultInitialBackoff)
+	}
+}
@@ -0,0 +1,7 @@
+// Package database holds types shared between pkg/reader's Readers and the
+// root database package's Dumpers, so rows read from one can be handed
+// straight to the other without conversion.
+package database
+
+// Row represents a single table row as a map of column name to value.
+type Row map[string]interface{}
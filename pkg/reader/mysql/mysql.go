@@ -0,0 +1,95 @@
+// Package mysql provides a MySQL implementation of reader.Driver/reader.Reader,
+// so a klepto dump can read its subsets straight from a MySQL source.
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/hellofresh/klepto/pkg/database/retry"
+	"github.com/hellofresh/klepto/pkg/reader"
+)
+
+func init() {
+	reader.Register("mysql", &driver{})
+}
+
+// dsnPattern recognises the DSNs this driver knows how to open: the
+// mysql:// URL form and the go-sql-driver `user:pass@tcp(host:port)/db` form.
+var dsnPattern = regexp.MustCompile(`^mysql://|@tcp\(`)
+
+// driver is the reader.Driver implementation for the one-shot snapshot
+// MySQL reader.
+type driver struct{}
+
+// IsSupported checks if the given DSN is a plain MySQL DSN. It explicitly
+// excludes mysql+binlog:// DSNs, which dsnPattern's `@tcp(` clause would
+// otherwise also match - those belong to the binlogDriver registered in
+// binlog.go, and reader.Connect has no preference order between drivers
+// registered in its sync.Map.
+func (d *driver) IsSupported(dsn string) bool {
+	if strings.HasPrefix(dsn, "mysql+binlog://") {
+		return false
+	}
+	return dsnPattern.MatchString(dsn)
+}
+
+// NewConnection opens a MySQL connection and returns a Reader backed by it.
+func (d *driver) NewConnection(opts reader.ConnOpts) (reader.Reader, error) {
+	dsn, err := buildDSN(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mysql connection: %w", err)
+	}
+
+	conn.SetMaxOpenConns(opts.MaxConns)
+	conn.SetMaxIdleConns(opts.MaxIdleConns)
+	conn.SetConnMaxLifetime(opts.MaxConnLifetime)
+
+	return &Reader{
+		conn:    conn,
+		timeout: opts.Timeout,
+		retryOpts: retry.Opts{
+			Max:            opts.RetryMax,
+			InitialBackoff: opts.RetryInitialBackoff,
+			MaxBackoff:     opts.RetryMaxBackoff,
+		},
+	}, nil
+}
+
+// buildDSN trims the mysql:// scheme off opts.DSN (if present) and appends
+// the read/write timeout and max-allowed-packet DSN params the
+// go-sql-driver/mysql driver understands.
+func buildDSN(opts reader.ConnOpts) (string, error) {
+	dsn := strings.TrimPrefix(opts.DSN, "mysql://")
+
+	params := make([]string, 0, 3)
+	if opts.ReadTimeout > 0 {
+		params = append(params, fmt.Sprintf("readTimeout=%s", opts.ReadTimeout))
+	}
+	if opts.WriteTimeout > 0 {
+		params = append(params, fmt.Sprintf("writeTimeout=%s", opts.WriteTimeout))
+	}
+	// A MaxAllowedPacket of 0 tells the driver to fetch max_allowed_packet
+	// from the server instead of enforcing a client-side value.
+	params = append(params, fmt.Sprintf("maxAllowedPacket=%d", opts.MaxAllowedPacket))
+
+	if len(params) == 0 {
+		return dsn, nil
+	}
+
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+
+	return dsn + sep + strings.Join(params, "&"), nil
+}
@@ -0,0 +1,203 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hellofresh/klepto/pkg/database"
+	"github.com/hellofresh/klepto/pkg/database/retry"
+	"github.com/hellofresh/klepto/pkg/reader"
+)
+
+// Reader is a MySQL backed reader.Reader.
+type Reader struct {
+	conn *sql.DB
+	// timeout bounds every individual statement issued against conn.
+	timeout time.Duration
+	// retryOpts governs how many times, and with what backoff, a retriable
+	// error (deadlock, lock wait timeout, dropped connection) is retried.
+	retryOpts retry.Opts
+}
+
+// withTimeout derives a per-statement context from ctx, honoring the
+// configured connection timeout in addition to whatever deadline ctx already
+// carries.
+func (r *Reader) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+// GetStructure returns the SQL used to create the database tables.
+func (r *Reader) GetStructure(ctx context.Context) (string, error) {
+	tables, err := r.GetTables(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var structure strings.Builder
+	for _, table := range tables {
+		stmt, err := r.getTableStructure(ctx, table)
+		if err != nil {
+			return "", err
+		}
+		structure.WriteString(stmt)
+		structure.WriteString(";\n")
+	}
+
+	return structure.String(), nil
+}
+
+func (r *Reader) getTableStructure(ctx context.Context, table string) (stmt string, err error) {
+	err = retry.Do(ctx, r.retryOpts, func(ctx context.Context) error {
+		sCtx, cancel := r.withTimeout(ctx)
+		defer cancel()
+
+		row := r.conn.QueryRowContext(sCtx, fmt.Sprintf("SHOW CREATE TABLE `%s`", table))
+
+		var tableName string
+		return row.Scan(&tableName, &stmt)
+	})
+	return
+}
+
+// GetTables returns a list of all databases tables.
+func (r *Reader) GetTables(ctx context.Context) (tables []string, err error) {
+	err = retry.Do(ctx, r.retryOpts, func(ctx context.Context) error {
+		sCtx, cancel := r.withTimeout(ctx)
+		defer cancel()
+
+		rows, err := r.conn.QueryContext(sCtx, "SHOW FULL TABLES")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		tables = make([]string, 0)
+		for rows.Next() {
+			var tableName, tableType string
+			if err := rows.Scan(&tableName, &tableType); err != nil {
+				return err
+			}
+			if tableType == "BASE TABLE" {
+				tables = append(tables, tableName)
+			}
+		}
+
+		return rows.Err()
+	})
+	return
+}
+
+// GetColumns return a list of all columns for a given table.
+func (r *Reader) GetColumns(ctx context.Context, table string) (columns []string, err error) {
+	err = retry.Do(ctx, r.retryOpts, func(ctx context.Context) error {
+		sCtx, cancel := r.withTimeout(ctx)
+		defer cancel()
+
+		rows, err := r.conn.QueryContext(sCtx, fmt.Sprintf("SELECT * FROM `%s` LIMIT 1", table))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		columns, err = rows.Columns()
+		return err
+	})
+	return
+}
+
+// FormatColumn returns a escaped table.column string.
+func (r *Reader) FormatColumn(tableName string, columnName string) string {
+	return fmt.Sprintf("`%s`.`%s`", tableName, columnName)
+}
+
+// ReadSubset returns a channel with all database rows. The read honors ctx
+// cancellation: if ctx is cancelled mid-scan, the in-flight *sql.Rows is
+// closed and the goroutine returns instead of blocking forever on the channel
+// send.
+func (r *Reader) ReadSubset(ctx context.Context, table string, batchSize int, rowChan chan<- database.Row, opt reader.ReadTableOpt) error {
+	defer close(rowChan)
+
+	query := r.buildQuery(table, opt)
+
+	var rows *sql.Rows
+	// cancelQuery releases the context the successful query was issued
+	// under. It must outlive the retry closure: cancelling it closes rows.
+	cancelQuery := func() {}
+	err := retry.Do(ctx, r.retryOpts, func(ctx context.Context) error {
+		sCtx, cancel := r.withTimeout(ctx)
+
+		var err error
+		rows, err = r.conn.QueryContext(sCtx, query)
+		if err != nil {
+			cancel()
+			return err
+		}
+		cancelQuery = cancel
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read subset of %q: %w", table, err)
+	}
+	defer cancelQuery()
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		row := make(database.Row, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+
+		select {
+		case rowChan <- row:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *Reader) buildQuery(table string, opt reader.ReadTableOpt) string {
+	query := fmt.Sprintf("SELECT * FROM `%s`", table)
+	for _, subset := range opt.Subsets {
+		if subset.Match != "" {
+			query += fmt.Sprintf(" WHERE %s", subset.Match)
+		}
+		if subset.Limit > 0 {
+			query += fmt.Sprintf(" LIMIT %d", subset.Limit)
+		}
+	}
+	return query
+}
+
+// Close closes the reader resources and releases them. It unblocks a
+// ReadSubset that's mid-query even if the ctx it was called with is never
+// cancelled: closing the *sql.DB closes its underlying connections out from
+// under any in-flight QueryContext/Scan, which returns an error on that
+// connection instead of hanging. Callers that want a clean cancellation
+// should still cancel ctx themselves; Close is the fallback for readers that
+// aren't watching one.
+func (r *Reader) Close() error {
+	return r.conn.Close()
+}
@@ -1,13 +1,24 @@
 package reader
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/hellofresh/klepto/pkg/config"
 	"github.com/hellofresh/klepto/pkg/database"
 )
 
+// drivers holds the registered Driver implementations, keyed by name.
+var drivers sync.Map
+
+// Register makes a driver available by the provided name to Connect.
+// Drivers usually call this from their package init().
+func Register(name string, driver Driver) {
+	drivers.Store(name, driver)
+}
+
 type (
 	// Driver is a driver interface used to support multiple drivers
 	Driver interface {
@@ -17,18 +28,22 @@ type (
 		NewConnection(ConnOpts) (Reader, error)
 	}
 
-	// Reader provides an interface to access database stores.
+	// Reader provides an interface to access database stores. Context
+	// propagation covers every driver in this tree, which is MySQL only
+	// (pkg/reader/mysql, both the one-shot and binlog-streaming modes); there
+	// is no Postgres reader here to update.
 	Reader interface {
 		// GetStructure returns the SQL used to create the database tables
-		GetStructure() (string, error)
+		GetStructure(ctx context.Context) (string, error)
 		// GetTables returns a list of all databases tables
-		GetTables() ([]string, error)
+		GetTables(ctx context.Context) ([]string, error)
 		// GetColumns return a list of all columns for a given table
-		GetColumns(string) ([]string, error)
+		GetColumns(ctx context.Context, table string) ([]string, error)
 		// FormatColumn returns a escaped table.column string
 		FormatColumn(tableName string, columnName string) string
-		// ReadSubset returns a channel with all database rows
-		ReadSubset(string, int, chan<- database.Row, ReadTableOpt) error
+		// ReadSubset returns a channel with all database rows. The read honors
+		// ctx cancellation/deadlines and unblocks in-flight queries when ctx is done.
+		ReadSubset(ctx context.Context, table string, batchSize int, rowChan chan<- database.Row, opt ReadTableOpt) error
 		// Close closes the reader resources and releases them.
 		Close() error
 	}
@@ -39,6 +54,11 @@ type (
 		Columns []string
 		// Subsets contains the subsets of the table
 		Subsets []*SubsetOpt
+		// StartPosition is the replication position a streaming Reader starts
+		// tailing from: for the MySQL binlog reader, a binlog "file:pos" pair,
+		// a GTID set, or "" for the master's current position. It is ignored
+		// by one-shot, snapshot-based readers.
+		StartPosition string
 	}
 
 	// RelationshipOpt represents the relationships options
@@ -72,12 +92,30 @@ type (
 		DSN string
 		// Timeout is the timeout for read operations.
 		Timeout time.Duration
+		// ReadTimeout bounds how long a single read (e.g. a row fetch) may
+		// block on the underlying connection before it is considered dead.
+		ReadTimeout time.Duration
+		// WriteTimeout bounds how long a single write may block on the
+		// underlying connection before it is considered dead.
+		WriteTimeout time.Duration
+		// MaxAllowedPacket caps the size of a single statement/row sent to or
+		// received from the server, in bytes. Zero means fetch the server's
+		// configured max_allowed_packet instead of setting one explicitly.
+		MaxAllowedPacket int
 		// MaxConnLifetime is the maximum amount of time a connection may be reused on the read database.
 		MaxConnLifetime time.Duration
 		// MaxConns is the maximum number of open connections to the read database.
 		MaxConns int
 		// MaxIdleConns is the maximum number of connections in the idle connection pool for the read database.
 		MaxIdleConns int
+		// RetryMax is the maximum number of retries for a retriable error
+		// (deadlocks, lock wait timeouts, dropped connections) before giving up.
+		RetryMax int
+		// RetryInitialBackoff is the delay before the first retry.
+		RetryInitialBackoff time.Duration
+		// RetryMaxBackoff caps the backoff between retries, before up to 50%
+		// jitter is added.
+		RetryMaxBackoff time.Duration
 	}
 )
 